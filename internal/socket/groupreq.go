@@ -0,0 +1,26 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package socket
+
+import "net"
+
+// A GroupReq represents a multicast group membership request,
+// mirroring the kernel's struct group_req used by the
+// MCAST_JOIN_GROUP/MCAST_LEAVE_GROUP family of socket options.
+type GroupReq struct {
+	Interface int
+	Group     net.Addr
+}
+
+// A GroupSourceReq represents a source-specific multicast group
+// membership or filter request, mirroring the kernel's struct
+// group_source_req used by MCAST_JOIN_SOURCE_GROUP,
+// MCAST_LEAVE_SOURCE_GROUP, MCAST_BLOCK_SOURCE and
+// MCAST_UNBLOCK_SOURCE.
+type GroupSourceReq struct {
+	Interface int
+	Group     net.Addr
+	Source    net.Addr
+}
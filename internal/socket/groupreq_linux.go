@@ -0,0 +1,85 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package socket
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+// sizeofSockaddrStorage matches the kernel's struct sockaddr_storage,
+// which struct group_req and struct group_source_req embed in place
+// of a bare struct sockaddr so that they can carry either an IPv4 or
+// an IPv6 address.
+const sizeofSockaddrStorage = 128
+
+// sizeofGroupReqHeader is the size of the gr_interface field plus
+// whatever padding the compiler inserts so that the following
+// sockaddr_storage starts aligned to its own required alignment --
+// 8 bytes on 64-bit archs, 4 bytes on 32-bit archs. It's defined in
+// the per-wordsize files alongside this one.
+
+// Marshal encodes g as the kernel's struct group_req:
+//
+//	struct group_req {
+//		uint32_t                gr_interface;
+//		uint32_t                gr_pad_cgo_0; // alignment padding, 64-bit archs only
+//		struct sockaddr_storage gr_group;
+//	};
+func (g *GroupReq) Marshal() ([]byte, error) {
+	b := make([]byte, sizeofGroupReqHeader+sizeofSockaddrStorage)
+	NativeEndian.PutUint32(b[:4], uint32(g.Interface))
+	sa, err := marshalSockaddrStorage(g.Group)
+	if err != nil {
+		return nil, err
+	}
+	copy(b[sizeofGroupReqHeader:], sa)
+	return b, nil
+}
+
+// Marshal encodes g as the kernel's struct group_source_req:
+//
+//	struct group_source_req {
+//		uint32_t                gsr_interface;
+//		uint32_t                gsr_pad_cgo_0; // alignment padding, 64-bit archs only
+//		struct sockaddr_storage gsr_group;
+//		struct sockaddr_storage gsr_source;
+//	};
+func (g *GroupSourceReq) Marshal() ([]byte, error) {
+	b := make([]byte, sizeofGroupReqHeader+2*sizeofSockaddrStorage)
+	NativeEndian.PutUint32(b[:4], uint32(g.Interface))
+	sa, err := marshalSockaddrStorage(g.Group)
+	if err != nil {
+		return nil, err
+	}
+	copy(b[sizeofGroupReqHeader:], sa)
+	sa, err = marshalSockaddrStorage(g.Source)
+	if err != nil {
+		return nil, err
+	}
+	copy(b[sizeofGroupReqHeader+sizeofSockaddrStorage:], sa)
+	return b, nil
+}
+
+func marshalSockaddrStorage(a net.Addr) ([]byte, error) {
+	var ip net.IP
+	switch a := a.(type) {
+	case *net.UDPAddr:
+		ip = a.IP
+	case *net.IPAddr:
+		ip = a.IP
+	default:
+		return nil, errors.New("socket: unsupported address type")
+	}
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return nil, errors.New("socket: not an IPv4 address")
+	}
+	b := make([]byte, sizeofSockaddrStorage)
+	NativeEndian.PutUint16(b[:2], uint16(syscall.AF_INET))
+	copy(b[4:8], ip4)
+	return b, nil
+}
@@ -0,0 +1,12 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux,386 linux,arm linux,mips linux,mipsle
+
+package socket
+
+// sizeofGroupReqHeader is just gr_interface (4 bytes): on these
+// 32-bit archs sockaddr_storage only requires 4-byte alignment, so
+// the compiler inserts no padding before it.
+const sizeofGroupReqHeader = 4
@@ -0,0 +1,13 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux,amd64 linux,arm64 linux,ppc64 linux,ppc64le linux,mips64 linux,mips64le linux,s390x
+
+package socket
+
+// sizeofGroupReqHeader is gr_interface (4 bytes) plus the 4 bytes of
+// padding the compiler inserts so that the following
+// sockaddr_storage, which requires 8-byte alignment on these archs,
+// starts on an 8-byte boundary.
+const sizeofGroupReqHeader = 8
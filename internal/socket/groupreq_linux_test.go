@@ -0,0 +1,52 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package socket
+
+import (
+	"net"
+	"testing"
+)
+
+func TestGroupReqMarshalSize(t *testing.T) {
+	g := &GroupReq{Interface: 1, Group: &net.IPAddr{IP: net.IPv4(232, 0, 0, 1)}}
+	b, err := g.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := sizeofGroupReqHeader + sizeofSockaddrStorage
+	if len(b) != want {
+		t.Fatalf("group_req size = %d, want %d", len(b), want)
+	}
+}
+
+func TestGroupSourceReqMarshalSize(t *testing.T) {
+	g := &GroupSourceReq{
+		Interface: 1,
+		Group:     &net.IPAddr{IP: net.IPv4(232, 0, 0, 1)},
+		Source:    &net.IPAddr{IP: net.IPv4(192, 0, 2, 1)},
+	}
+	b, err := g.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := sizeofGroupReqHeader + 2*sizeofSockaddrStorage
+	if len(b) != want {
+		t.Fatalf("group_source_req size = %d, want %d", len(b), want)
+	}
+	// gr_interface lives at offset 0, the group sockaddr_storage is
+	// padded out to start at sizeofGroupReqHeader, and the source
+	// sockaddr_storage follows it sizeofSockaddrStorage bytes later.
+	if got := NativeEndian.Uint32(b[:4]); got != 1 {
+		t.Fatalf("gsr_interface = %d, want 1", got)
+	}
+	grpOff := sizeofGroupReqHeader
+	if b[grpOff] == 0 && b[grpOff+1] == 0 {
+		t.Fatalf("gsr_group.ss_family not written at offset %d", grpOff)
+	}
+	srcOff := sizeofGroupReqHeader + sizeofSockaddrStorage
+	if b[srcOff] == 0 && b[srcOff+1] == 0 {
+		t.Fatalf("gsr_source.ss_family not written at offset %d", srcOff)
+	}
+}
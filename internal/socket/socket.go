@@ -0,0 +1,74 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package socket provides a portable abstraction over the raw file
+// descriptor underlying a net.Conn or net.PacketConn, for packages
+// such as ipv4 and ipv6 that need to issue setsockopt/getsockopt,
+// recvmsg/sendmsg and similar system calls directly.
+package socket
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+// ErrInvalidConn is returned when a method is called on a Conn whose
+// underlying connection is nil.
+var ErrInvalidConn = errors.New("invalid connection")
+
+// ErrNotSupported is returned when the underlying connection doesn't
+// expose a raw file descriptor, or when the requested operation isn't
+// implemented on the running platform.
+var ErrNotSupported = errors.New("not supported")
+
+// A Conn wraps the raw file descriptor of a net.Conn or
+// net.PacketConn so that callers can run socket system calls against
+// it without depending on the concrete stdlib type.
+type Conn struct {
+	rc syscall.RawConn
+}
+
+// NewConn returns a new Conn backed by c's underlying file
+// descriptor. It returns ErrNotSupported if c doesn't implement
+// syscall.Conn, which every concrete net.Conn/net.PacketConn in the
+// standard library does.
+func NewConn(c interface{}) (*Conn, error) {
+	sc, ok := c.(syscall.Conn)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	rc, err := sc.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{rc: rc}, nil
+}
+
+// Ok reports whether o is usable.
+func (o *Conn) Ok() bool { return o != nil && o.rc != nil }
+
+// SysFD runs f with the connection's underlying file descriptor and
+// returns whatever error f, or obtaining the descriptor, produced.
+func (o *Conn) SysFD(f func(fd int) error) error {
+	if !o.Ok() {
+		return ErrInvalidConn
+	}
+	var operr error
+	if err := o.rc.Control(func(s uintptr) { operr = f(int(s)) }); err != nil {
+		return err
+	}
+	return operr
+}
+
+// A Message represents an IO message sent or received via the batched
+// ReadBatch/WriteBatch paths in ipv4 and ipv6.
+type Message struct {
+	Buffers [][]byte
+	OOB     []byte
+	Addr    net.Addr
+	N       int
+	NN      int
+	Flags   int
+}
@@ -0,0 +1,46 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package socket
+
+import (
+	"encoding/binary"
+	"unsafe"
+)
+
+// NativeEndian is the byte order of the running architecture. Fields
+// of raw kernel structs such as sockaddr.sa_family are stored in host
+// byte order, unlike fields such as sockaddr_in.sin_port which the
+// kernel always stores in network (big-endian) byte order regardless
+// of the host's own endianness. Using the wrong one of these two
+// breaks marshaling on big-endian architectures such as s390x and
+// mips.
+var NativeEndian = nativeEndian()
+
+func nativeEndian() binary.ByteOrder {
+	var i uint16 = 1
+	b := (*[2]byte)(unsafe.Pointer(&i))
+	if b[0] == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
+
+// Htons converts a host byte order port into the uint16 value that,
+// once stored in a native-endian struct field such as
+// sockaddr_in.sin_port, holds the correct network (big-endian) byte
+// order on the wire on any architecture.
+func Htons(port int) uint16 {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, uint16(port))
+	return NativeEndian.Uint16(b)
+}
+
+// Ntohs reverses Htons, recovering a host byte order port from a
+// native-endian struct field that holds a network byte order value.
+func Ntohs(v uint16) int {
+	b := make([]byte, 2)
+	NativeEndian.PutUint16(b, v)
+	return int(binary.BigEndian.Uint16(b))
+}
@@ -0,0 +1,101 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipv4
+
+import "golang.org/x/net/internal/socket"
+
+// BUG(mikio): On platforms other than Linux, the ReadBatch and
+// WriteBatch methods of PacketConn and RawConn issue one ReadFrom or
+// WriteTo call per message, ignore flags, and leave the OOB and NN
+// fields of each Message untouched.
+
+// A Message represents an IO message.
+//
+//	type Message struct {
+//		Buffers [][]byte
+//		OOB     []byte
+//		Addr    net.Addr
+//		N       int
+//		NN      int
+//		Flags   int
+//	}
+//
+// The Buffers fields represents a list of contiguous buffers, which
+// can be used for scatter-gather I/O, most notably the vectored I/O.
+// N is the number of bytes read or written from/to Buffers.
+// OOB, on platforms that support out-of-band data, represents the
+// out-of-band data of the message. NN is the number of bytes read
+// or written from/to OOB.
+//
+// The Addr field is used to hold a destination address for
+// WriteBatch. On ReadBatch, it holds the source address for the
+// received packet, if the underlying protocol is connectionless.
+//
+// Message is an alias for socket.Message so that the batch I/O path
+// here and in the internal socket package share one definition.
+type Message = socket.Message
+
+// ReadBatch reads a batch of messages.
+//
+// The provided flags is a set of platform-dependent flags, such as
+// syscall.MSG_PEEK.
+//
+// On a successful read it returns the number of messages received, up
+// to len(ms). On each message received, the corresponding Buffers,
+// OOB, N, NN and Addr fields are updated. Use Message.N to determine
+// how many bytes were actually read, not the length of Buffers or OOB.
+//
+// Unlike ReadFrom, a single call to ReadBatch issues only one
+// underlying read on platforms that support recvmmsg(2)-like batched
+// receive, which amortizes the per-packet syscall overhead across the
+// whole batch. On other platforms it falls back to repeated calls to
+// ReadFrom.
+func (c *payloadHandler) ReadBatch(ms []Message, flags int) (int, error) {
+	if !c.ok() {
+		return 0, errInvalidConn
+	}
+	return c.readBatch(ms, flags)
+}
+
+// WriteBatch writes a batch of messages.
+//
+// The provided flags is a set of platform-dependent flags, such as
+// syscall.MSG_DONTROUTE.
+//
+// It returns the number of messages sent from ms, up to len(ms). On
+// each message sent, the N field is updated to indicate the number
+// of payload bytes written.
+//
+// Like ReadBatch, a single call to WriteBatch issues only one
+// underlying write on platforms that support sendmmsg(2)-like batched
+// send.
+func (c *payloadHandler) WriteBatch(ms []Message, flags int) (int, error) {
+	if !c.ok() {
+		return 0, errInvalidConn
+	}
+	return c.writeBatch(ms, flags)
+}
+
+// ReadBatch reads a batch of messages.
+//
+// See the PacketConn.ReadBatch method of PacketConn for further
+// information.
+func (c *packetHandler) ReadBatch(ms []Message, flags int) (int, error) {
+	if !c.ok() {
+		return 0, errInvalidConn
+	}
+	return c.readBatch(ms, flags)
+}
+
+// WriteBatch writes a batch of messages.
+//
+// See the PacketConn.WriteBatch method of PacketConn for further
+// information.
+func (c *packetHandler) WriteBatch(ms []Message, flags int) (int, error) {
+	if !c.ok() {
+		return 0, errInvalidConn
+	}
+	return c.writeBatch(ms, flags)
+}
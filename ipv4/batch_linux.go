@@ -0,0 +1,198 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipv4
+
+import (
+	"net"
+	"os"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/net/internal/socket"
+)
+
+// mmsghdr is the Linux struct mmsghdr used by recvmmsg(2)/sendmmsg(2).
+type mmsghdr struct {
+	Hdr syscall.Msghdr
+	Len uint32
+}
+
+func (h *mmsghdr) pack(vs []syscall.Iovec, oob []byte, rsa *syscall.RawSockaddrInet4) {
+	h.Hdr.Iov = &vs[0]
+	h.Hdr.SetIovlen(len(vs))
+	if len(oob) > 0 {
+		h.Hdr.Control = &oob[0]
+		h.Hdr.SetControllen(len(oob))
+	}
+	if rsa != nil {
+		h.Hdr.Name = (*byte)(unsafe.Pointer(rsa))
+		h.Hdr.Namelen = syscall.SizeofSockaddrInet4
+	}
+}
+
+func (c *payloadHandler) readBatch(ms []Message, flags int) (int, error) {
+	hs := make([]mmsghdr, len(ms))
+	sas := make([]syscall.RawSockaddrInet4, len(ms))
+	for i := range ms {
+		vs := iovecs(ms[i].Buffers)
+		hs[i].pack(vs, ms[i].OOB, &sas[i])
+	}
+	var n int
+	err := c.so.SysFD(func(fd int) (err error) {
+		n, err = recvMMsg(fd, hs, flags)
+		return err
+	})
+	if err != nil {
+		return n, os.NewSyscallError("recvmmsg", err)
+	}
+	for i := 0; i < n; i++ {
+		ms[i].N = int(hs[i].Len)
+		ms[i].NN = int(hs[i].Hdr.Controllen)
+		ms[i].Addr = netAddrFromSockaddrInet4(&sas[i])
+	}
+	return n, nil
+}
+
+func (c *payloadHandler) writeBatch(ms []Message, flags int) (int, error) {
+	hs := make([]mmsghdr, len(ms))
+	sas := make([]syscall.RawSockaddrInet4, len(ms))
+	for i := range ms {
+		vs := iovecs(ms[i].Buffers)
+		var rsa *syscall.RawSockaddrInet4
+		if ms[i].Addr != nil {
+			if err := sockaddrInet4FromNetAddr(&sas[i], ms[i].Addr); err != nil {
+				return i, err
+			}
+			rsa = &sas[i]
+		}
+		hs[i].pack(vs, ms[i].OOB, rsa)
+	}
+	var n int
+	err := c.so.SysFD(func(fd int) (err error) {
+		n, err = sendMMsg(fd, hs, flags)
+		return err
+	})
+	if err != nil {
+		return n, os.NewSyscallError("sendmmsg", err)
+	}
+	for i := 0; i < n; i++ {
+		ms[i].N = int(hs[i].Len)
+	}
+	return n, nil
+}
+
+func (c *packetHandler) readBatch(ms []Message, flags int) (int, error) {
+	hs := make([]mmsghdr, len(ms))
+	sas := make([]syscall.RawSockaddrInet4, len(ms))
+	for i := range ms {
+		vs := iovecs(ms[i].Buffers)
+		hs[i].pack(vs, ms[i].OOB, &sas[i])
+	}
+	var n int
+	err := c.so.SysFD(func(fd int) (err error) {
+		n, err = recvMMsg(fd, hs, flags)
+		return err
+	})
+	if err != nil {
+		return n, os.NewSyscallError("recvmmsg", err)
+	}
+	for i := 0; i < n; i++ {
+		ms[i].N = int(hs[i].Len)
+		ms[i].NN = int(hs[i].Hdr.Controllen)
+		ms[i].Addr = netAddrFromSockaddrInet4(&sas[i])
+	}
+	return n, nil
+}
+
+func (c *packetHandler) writeBatch(ms []Message, flags int) (int, error) {
+	hs := make([]mmsghdr, len(ms))
+	sas := make([]syscall.RawSockaddrInet4, len(ms))
+	for i := range ms {
+		vs := iovecs(ms[i].Buffers)
+		var rsa *syscall.RawSockaddrInet4
+		if ms[i].Addr != nil {
+			if err := sockaddrInet4FromNetAddr(&sas[i], ms[i].Addr); err != nil {
+				return i, err
+			}
+			rsa = &sas[i]
+		}
+		hs[i].pack(vs, ms[i].OOB, rsa)
+	}
+	var n int
+	err := c.so.SysFD(func(fd int) (err error) {
+		n, err = sendMMsg(fd, hs, flags)
+		return err
+	})
+	if err != nil {
+		return n, os.NewSyscallError("sendmmsg", err)
+	}
+	for i := 0; i < n; i++ {
+		ms[i].N = int(hs[i].Len)
+	}
+	return n, nil
+}
+
+// recvMMsg wraps the recvmmsg(2) syscall, issuing a single batched
+// receive for up to len(hs) messages.
+func recvMMsg(fd int, hs []mmsghdr, flags int) (int, error) {
+	n, _, errno := syscall.Syscall6(syscall.SYS_RECVMMSG, uintptr(fd), uintptr(unsafe.Pointer(&hs[0])), uintptr(len(hs)), uintptr(flags), 0, 0)
+	if errno != 0 {
+		return int(n), errno
+	}
+	return int(n), nil
+}
+
+// sendMMsg wraps the sendmmsg(2) syscall, issuing a single batched
+// send for up to len(hs) messages.
+func sendMMsg(fd int, hs []mmsghdr, flags int) (int, error) {
+	n, _, errno := syscall.Syscall6(syscall.SYS_SENDMMSG, uintptr(fd), uintptr(unsafe.Pointer(&hs[0])), uintptr(len(hs)), uintptr(flags), 0, 0)
+	if errno != 0 {
+		return int(n), errno
+	}
+	return int(n), nil
+}
+
+func iovecs(buffers [][]byte) []syscall.Iovec {
+	vs := make([]syscall.Iovec, len(buffers))
+	for i, b := range buffers {
+		vs[i].SetLen(len(b))
+		if len(b) > 0 {
+			vs[i].Base = &b[0]
+		}
+	}
+	return vs
+}
+
+// netAddrFromSockaddrInet4 and sockaddrInet4FromNetAddr convert
+// between net.Addr and the kernel's struct sockaddr_in. sin_port is
+// always stored in network (big-endian) byte order by the kernel
+// regardless of the host's own endianness, so it must go through
+// socket.Htons/socket.Ntohs rather than a hardcoded byte swap, which
+// only happens to be correct on little-endian hosts.
+func netAddrFromSockaddrInet4(rsa *syscall.RawSockaddrInet4) net.Addr {
+	return &net.UDPAddr{IP: append([]byte(nil), rsa.Addr[:]...), Port: socket.Ntohs(rsa.Port)}
+}
+
+func sockaddrInet4FromNetAddr(rsa *syscall.RawSockaddrInet4, a net.Addr) error {
+	ip, port, err := ipAndPort(a)
+	if err != nil {
+		return err
+	}
+	rsa.Family = syscall.AF_INET
+	rsa.Port = socket.Htons(port)
+	copy(rsa.Addr[:], ip.To4())
+	return nil
+}
+
+func ipAndPort(a net.Addr) (net.IP, int, error) {
+	switch a := a.(type) {
+	case *net.UDPAddr:
+		return a.IP, a.Port, nil
+	case *net.IPAddr:
+		return a.IP, 0, nil
+	default:
+		return nil, 0, &net.AddrError{Err: "unsupported address type for batch I/O", Addr: a.String()}
+	}
+}
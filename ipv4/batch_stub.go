@@ -0,0 +1,129 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !linux
+
+package ipv4
+
+import "net"
+
+// readBatch emulates batched reads by issuing one ReadFrom call per
+// requested message. It stops at the first error, returning the
+// number of messages successfully filled in.
+func (c *payloadHandler) readBatch(ms []Message, flags int) (int, error) {
+	n := 0
+	for i := range ms {
+		nn, addr, err := readFromBuffers(ms[i].Buffers, c.c.ReadFrom)
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		ms[i].N = nn
+		ms[i].Addr = addr
+		n++
+	}
+	return n, nil
+}
+
+// writeBatch emulates batched writes by issuing one WriteTo call per
+// message.
+func (c *payloadHandler) writeBatch(ms []Message, flags int) (int, error) {
+	n := 0
+	for i := range ms {
+		buf := concat(ms[i].Buffers)
+		nn, err := c.c.WriteTo(buf, ms[i].Addr)
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		ms[i].N = nn
+		n++
+	}
+	return n, nil
+}
+
+func (c *packetHandler) readBatch(ms []Message, flags int) (int, error) {
+	n := 0
+	for i := range ms {
+		nn, addr, err := readFromBuffers(ms[i].Buffers, c.c.ReadFrom)
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		ms[i].N = nn
+		ms[i].Addr = addr
+		n++
+	}
+	return n, nil
+}
+
+func (c *packetHandler) writeBatch(ms []Message, flags int) (int, error) {
+	n := 0
+	for i := range ms {
+		buf := concat(ms[i].Buffers)
+		nn, err := c.c.WriteTo(buf, ms[i].Addr)
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		ms[i].N = nn
+		n++
+	}
+	return n, nil
+}
+
+// readFromBuffers reads a single datagram via read into buffers. When
+// there's only one buffer it's used directly; otherwise the datagram
+// is read into a scratch buffer and scattered back across buffers
+// afterwards, so callers relying on multi-buffer Messages still see
+// their data on platforms without a native scatter read.
+func readFromBuffers(buffers [][]byte, read func([]byte) (int, net.Addr, error)) (int, net.Addr, error) {
+	if len(buffers) == 1 {
+		return read(buffers[0])
+	}
+	var total int
+	for _, b := range buffers {
+		total += len(b)
+	}
+	scratch := make([]byte, total)
+	n, addr, err := read(scratch)
+	if err != nil {
+		return n, addr, err
+	}
+	scatter(buffers, scratch[:n])
+	return n, addr, nil
+}
+
+func scatter(buffers [][]byte, b []byte) {
+	for _, buf := range buffers {
+		if len(b) == 0 {
+			break
+		}
+		nn := copy(buf, b)
+		b = b[nn:]
+	}
+}
+
+func concat(buffers [][]byte) []byte {
+	if len(buffers) == 1 {
+		return buffers[0]
+	}
+	var n int
+	for _, b := range buffers {
+		n += len(b)
+	}
+	buf := make([]byte, 0, n)
+	for _, b := range buffers {
+		buf = append(buf, b...)
+	}
+	return buf
+}
@@ -6,8 +6,9 @@ package ipv4
 
 import (
 	"net"
-	"syscall"
 	"time"
+
+	"golang.org/x/net/internal/socket"
 )
 
 // A Conn represents a network endpoint that uses the IPv4 transport.
@@ -18,21 +19,36 @@ type Conn struct {
 }
 
 type genericOpt struct {
-	c net.Conn
+	c   net.Conn
+	so  *socket.Conn
+	err error
 }
 
-func (c *genericOpt) ok() bool { return c != nil && c.c != nil }
+func (c *genericOpt) ok() bool { return c != nil && c.c != nil && c.so != nil }
+
+// invalidConnErr reports why c isn't usable: the error socket.NewConn
+// produced when c was constructed, if any, otherwise the generic
+// errInvalidConn.
+func (c *genericOpt) invalidConnErr() error {
+	if c.err != nil {
+		return opError(c.err)
+	}
+	return errInvalidConn
+}
 
 // NewConn returns a new Conn.
 func NewConn(c net.Conn) *Conn {
+	so, err := socket.NewConn(c)
 	return &Conn{
-		genericOpt: genericOpt{c},
+		genericOpt: genericOpt{c: c, so: so, err: err},
 	}
 }
 
 // A PacketConn represents a packet network endpoint that uses the
 // IPv4 transport.  It is used to control several IP-level socket
-// options including multicasting.  It also provides datagram based
+// options including multicasting, such as any-source joins via
+// JoinGroup and source-specific (SSM) joins via
+// JoinSourceSpecificGroup.  It also provides datagram based
 // network I/O methods specific to the IPv4 and higher layer protocols
 // such as UDP.
 type PacketConn struct {
@@ -42,28 +58,38 @@ type PacketConn struct {
 }
 
 type dgramOpt struct {
-	c net.PacketConn
+	c   net.PacketConn
+	so  *socket.Conn
+	err error
 }
 
-func (c *dgramOpt) ok() bool { return c != nil && c.c != nil }
+func (c *dgramOpt) ok() bool { return c != nil && c.c != nil && c.so != nil }
+
+// invalidConnErr reports why c isn't usable: the error socket.NewConn
+// produced when c was constructed, if any, otherwise the generic
+// errInvalidConn.
+func (c *dgramOpt) invalidConnErr() error {
+	if c.err != nil {
+		return opError(c.err)
+	}
+	return errInvalidConn
+}
 
 // SetControlMessage sets the per packet IP-level socket options.
 func (c *PacketConn) SetControlMessage(cf ControlFlags, on bool) error {
 	if !c.payloadHandler.ok() {
-		return syscall.EINVAL
+		return c.dgramOpt.invalidConnErr()
 	}
-	fd, err := c.payloadHandler.sysfd()
-	if err != nil {
-		return err
-	}
-	return setControlMessage(fd, &c.payloadHandler.rawOpt, cf, on)
+	return c.payloadHandler.so.SysFD(func(fd int) error {
+		return setControlMessage(fd, &c.payloadHandler.rawOpt, cf, on)
+	})
 }
 
 // SetDeadline sets the read and write deadlines associated with the
 // endpoint.
 func (c *PacketConn) SetDeadline(t time.Time) error {
 	if !c.payloadHandler.ok() {
-		return syscall.EINVAL
+		return c.dgramOpt.invalidConnErr()
 	}
 	return c.payloadHandler.c.SetDeadline(t)
 }
@@ -72,7 +98,7 @@ func (c *PacketConn) SetDeadline(t time.Time) error {
 // endpoint.
 func (c *PacketConn) SetReadDeadline(t time.Time) error {
 	if !c.payloadHandler.ok() {
-		return syscall.EINVAL
+		return c.dgramOpt.invalidConnErr()
 	}
 	return c.payloadHandler.c.SetReadDeadline(t)
 }
@@ -81,7 +107,7 @@ func (c *PacketConn) SetReadDeadline(t time.Time) error {
 // endpoint.
 func (c *PacketConn) SetWriteDeadline(t time.Time) error {
 	if !c.payloadHandler.ok() {
-		return syscall.EINVAL
+		return c.dgramOpt.invalidConnErr()
 	}
 	return c.payloadHandler.c.SetWriteDeadline(t)
 }
@@ -89,7 +115,7 @@ func (c *PacketConn) SetWriteDeadline(t time.Time) error {
 // Close closes the endpoint.
 func (c *PacketConn) Close() error {
 	if !c.payloadHandler.ok() {
-		return syscall.EINVAL
+		return c.dgramOpt.invalidConnErr()
 	}
 	return c.payloadHandler.c.Close()
 }
@@ -97,10 +123,11 @@ func (c *PacketConn) Close() error {
 // NewPacketConn returns a new PacketConn using c as its underlying
 // transport.
 func NewPacketConn(c net.PacketConn) *PacketConn {
+	so, err := socket.NewConn(c)
 	return &PacketConn{
-		genericOpt:     genericOpt{c.(net.Conn)},
-		dgramOpt:       dgramOpt{c},
-		payloadHandler: payloadHandler{c: c},
+		genericOpt:     genericOpt{c: c.(net.Conn), so: so, err: err},
+		dgramOpt:       dgramOpt{c: c, so: so, err: err},
+		payloadHandler: payloadHandler{c: c, so: so},
 	}
 }
 
@@ -118,20 +145,18 @@ type RawConn struct {
 // SetControlMessage sets the per packet IP-level socket options.
 func (c *RawConn) SetControlMessage(cf ControlFlags, on bool) error {
 	if !c.packetHandler.ok() {
-		return syscall.EINVAL
-	}
-	fd, err := c.packetHandler.sysfd()
-	if err != nil {
-		return err
+		return c.dgramOpt.invalidConnErr()
 	}
-	return setControlMessage(fd, &c.packetHandler.rawOpt, cf, on)
+	return c.packetHandler.so.SysFD(func(fd int) error {
+		return setControlMessage(fd, &c.packetHandler.rawOpt, cf, on)
+	})
 }
 
 // SetDeadline sets the read and write deadlines associated with the
 // endpoint.
 func (c *RawConn) SetDeadline(t time.Time) error {
 	if !c.packetHandler.ok() {
-		return syscall.EINVAL
+		return c.dgramOpt.invalidConnErr()
 	}
 	return c.packetHandler.c.SetDeadline(t)
 }
@@ -140,7 +165,7 @@ func (c *RawConn) SetDeadline(t time.Time) error {
 // endpoint.
 func (c *RawConn) SetReadDeadline(t time.Time) error {
 	if !c.packetHandler.ok() {
-		return syscall.EINVAL
+		return c.dgramOpt.invalidConnErr()
 	}
 	return c.packetHandler.c.SetReadDeadline(t)
 }
@@ -149,7 +174,7 @@ func (c *RawConn) SetReadDeadline(t time.Time) error {
 // endpoint.
 func (c *RawConn) SetWriteDeadline(t time.Time) error {
 	if !c.packetHandler.ok() {
-		return syscall.EINVAL
+		return c.dgramOpt.invalidConnErr()
 	}
 	return c.packetHandler.c.SetWriteDeadline(t)
 }
@@ -157,7 +182,7 @@ func (c *RawConn) SetWriteDeadline(t time.Time) error {
 // Close closes the endpoint.
 func (c *RawConn) Close() error {
 	if !c.packetHandler.ok() {
-		return syscall.EINVAL
+		return c.dgramOpt.invalidConnErr()
 	}
 	return c.packetHandler.c.Close()
 }
@@ -165,16 +190,22 @@ func (c *RawConn) Close() error {
 // NewRawConn returns a new RawConn using c as its underlying
 // transport.
 func NewRawConn(c net.PacketConn) (*RawConn, error) {
-	r := &RawConn{
-		genericOpt:    genericOpt{c.(net.Conn)},
-		dgramOpt:      dgramOpt{c},
-		packetHandler: packetHandler{c: c.(*net.IPConn)},
+	ipc, ok := c.(*net.IPConn)
+	if !ok {
+		return nil, errNotSupported
 	}
-	fd, err := r.packetHandler.sysfd()
+	so, err := socket.NewConn(c)
 	if err != nil {
 		return nil, err
 	}
-	if err := setIPv4HeaderPrepend(fd, true); err != nil {
+	r := &RawConn{
+		genericOpt:    genericOpt{c: c.(net.Conn), so: so},
+		dgramOpt:      dgramOpt{c: c, so: so},
+		packetHandler: packetHandler{c: ipc, so: so},
+	}
+	if err := r.packetHandler.so.SysFD(func(fd int) error {
+		return setIPv4HeaderPrepend(fd, true)
+	}); err != nil {
 		return nil, err
 	}
 	return r, nil
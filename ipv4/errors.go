@@ -0,0 +1,27 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipv4
+
+import (
+	"errors"
+	"net"
+
+	"golang.org/x/net/internal/socket"
+)
+
+// errInvalidConn and errNotSupported wrap the sentinel errors from
+// internal/socket in a *net.OpError, so that callers can use
+// errors.Is against the socket.ErrInvalidConn/ErrNotSupported values
+// while still getting a net.Error-compatible error out of ipv4's API.
+var (
+	errInvalidConn  = opError(socket.ErrInvalidConn)
+	errNotSupported = opError(socket.ErrNotSupported)
+
+	errMissingAddress = opError(errors.New("missing address"))
+)
+
+func opError(err error) error {
+	return &net.OpError{Op: "ipv4", Err: err}
+}
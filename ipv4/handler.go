@@ -0,0 +1,56 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipv4
+
+import (
+	"net"
+
+	"golang.org/x/net/internal/socket"
+)
+
+// ControlFlags represents per packet IP-level socket options, set
+// with (*PacketConn).SetControlMessage or
+// (*RawConn).SetControlMessage.
+type ControlFlags uint
+
+// Per packet IP-level socket options.
+const (
+	FlagTTL ControlFlags = 1 << iota
+	FlagSrc
+	FlagDst
+	FlagInterface
+)
+
+// rawOpt holds the per packet IP-level socket options enabled via
+// SetControlMessage.
+type rawOpt struct {
+	cflags ControlFlags
+}
+
+func (c *rawOpt) set(cf ControlFlags)        { c.cflags |= cf }
+func (c *rawOpt) clear(cf ControlFlags)      { c.cflags &^= cf }
+func (c *rawOpt) isset(cf ControlFlags) bool { return c.cflags&cf != 0 }
+
+// payloadHandler wraps the net.PacketConn backing a PacketConn so
+// that its socket options and batched I/O go through the same
+// *socket.Conn as genericOpt/dgramOpt, instead of each method pulling
+// the file descriptor out on its own.
+type payloadHandler struct {
+	c net.PacketConn
+	rawOpt
+	so *socket.Conn
+}
+
+func (c *payloadHandler) ok() bool { return c != nil && c.c != nil && c.so != nil }
+
+// packetHandler wraps the *net.IPConn backing a RawConn, following
+// the same pattern as payloadHandler.
+type packetHandler struct {
+	c *net.IPConn
+	rawOpt
+	so *socket.Conn
+}
+
+func (c *packetHandler) ok() bool { return c != nil && c.c != nil && c.so != nil }
@@ -0,0 +1,84 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipv4
+
+import "net"
+
+// MTU discovery modes for SetMTUDiscover, mirroring the kernel's
+// IP_PMTUDISC_* values.
+const (
+	MTUDiscoverDont  = iota // never send DF and ignore path MTU
+	MTUDiscoverWant         // use per-route hints
+	MTUDiscoverDo           // always send DF, report EMSGSIZE on overflow
+	MTUDiscoverProbe        // set DF but ignore path MTU
+)
+
+// PathMTU returns the path MTU the kernel has cached for the
+// connected peer of c.
+//
+// It's implemented on Linux only; on other platforms, or when the
+// kernel has no cached estimate for a connected peer, it returns an
+// error.
+func (c *Conn) PathMTU() (int, error) {
+	if !c.ok() {
+		return 0, c.invalidConnErr()
+	}
+	var mtu int
+	err := c.so.SysFD(func(fd int) (err error) {
+		mtu, err = getMTU(fd)
+		return err
+	})
+	return mtu, err
+}
+
+// PathMTU returns the peer address of c, if any, and the path MTU the
+// kernel has cached for it.
+//
+// A caller doing DF-marked sends over a connected PacketConn can
+// call PathMTU after a write fails with EMSGSIZE and resend once the
+// new estimate is known, rather than reopening the socket. On an
+// unconnected PacketConn, PathMTU returns an error. For a
+// faster turnaround than polling PathMTU, call SetRecvErr(true) and
+// pass ReadFrom's OOB bytes to ParseRecvErr: once the kernel delivers
+// the ICMP "fragmentation needed" notification, the returned RecvErr
+// carries the same updated MTU in its Info field without a separate
+// query.
+func (c *PacketConn) PathMTU() (net.IP, int, error) {
+	if !c.dgramOpt.ok() {
+		return nil, 0, c.dgramOpt.invalidConnErr()
+	}
+	var raddr net.IP
+	var mtu int
+	err := c.dgramOpt.so.SysFD(func(fd int) (err error) {
+		mtu, err = getMTU(fd)
+		if err != nil {
+			return err
+		}
+		raddr, _ = peerAddr(fd)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return raddr, mtu, nil
+}
+
+// SetMTUDiscover sets the path MTU discovery mode, one of
+// MTUDiscoverDont, MTUDiscoverWant, MTUDiscoverDo or
+// MTUDiscoverProbe.
+//
+// When mode is MTUDiscoverDo or MTUDiscoverProbe, outgoing datagrams
+// carry the Don't Fragment bit and a write that would require
+// fragmentation fails with EMSGSIZE; the kernel's updated path MTU
+// estimate can then be read back with PathMTU without reopening the
+// socket.
+func (c *dgramOpt) SetMTUDiscover(mode int) error {
+	if !c.ok() {
+		return c.invalidConnErr()
+	}
+	return c.so.SysFD(func(fd int) error {
+		return setMTUDiscover(fd, mode)
+	})
+}
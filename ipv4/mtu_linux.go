@@ -0,0 +1,32 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipv4
+
+import (
+	"net"
+	"syscall"
+)
+
+func getMTU(fd int) (int, error) {
+	return syscall.GetsockoptInt(fd, syscall.IPPROTO_IP, syscall.IP_MTU)
+}
+
+func setMTUDiscover(fd, mode int) error {
+	return syscall.SetsockoptInt(fd, syscall.IPPROTO_IP, syscall.IP_MTU_DISCOVER, mode)
+}
+
+func peerAddr(fd int) (net.IP, error) {
+	sa, err := syscall.Getpeername(fd)
+	if err != nil {
+		return nil, err
+	}
+	sa4, ok := sa.(*syscall.SockaddrInet4)
+	if !ok {
+		return nil, errNotSupported
+	}
+	ip := make(net.IP, net.IPv4len)
+	copy(ip, sa4.Addr[:])
+	return ip, nil
+}
@@ -0,0 +1,21 @@
+// Copyright 2016 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !linux
+
+package ipv4
+
+import "net"
+
+func getMTU(fd int) (int, error) {
+	return 0, errNotSupported
+}
+
+func setMTUDiscover(fd, mode int) error {
+	return errNotSupported
+}
+
+func peerAddr(fd int) (net.IP, error) {
+	return nil, errNotSupported
+}
@@ -0,0 +1,101 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipv4
+
+import "net"
+
+// JoinSourceSpecificGroup joins the source-specific multicast group
+// comprising group on ifi, accepting traffic only from source. It's
+// the source-specific multicast (SSM) counterpart of JoinGroup and is
+// typically used to implement IGMPv3 receivers, for example SAP/SDP
+// or IPTV listeners, against well-known SSM addresses in 232.0.0.0/8.
+//
+// If ifi is nil, JoinSourceSpecificGroup uses the system-assigned
+// multicast interface.
+func (c *dgramOpt) JoinSourceSpecificGroup(ifi *net.Interface, group, source net.Addr) error {
+	if !c.ok() {
+		return errInvalidConn
+	}
+	grp, src, err := groupSourceIP4(group, source)
+	if err != nil {
+		return err
+	}
+	return c.so.SysFD(func(fd int) error {
+		return joinLeaveSourceSpecificGroup(fd, ifi, grp, src, true)
+	})
+}
+
+// LeaveSourceSpecificGroup leaves the source-specific multicast group
+// comprising group and source on ifi that was previously joined with
+// JoinSourceSpecificGroup.
+func (c *dgramOpt) LeaveSourceSpecificGroup(ifi *net.Interface, group, source net.Addr) error {
+	if !c.ok() {
+		return errInvalidConn
+	}
+	grp, src, err := groupSourceIP4(group, source)
+	if err != nil {
+		return err
+	}
+	return c.so.SysFD(func(fd int) error {
+		return joinLeaveSourceSpecificGroup(fd, ifi, grp, src, false)
+	})
+}
+
+// ExcludeSourceSpecificGroup excludes traffic from source on the
+// already-joined multicast group on ifi, turning an any-source join
+// into a source filter list.
+func (c *dgramOpt) ExcludeSourceSpecificGroup(ifi *net.Interface, group, source net.Addr) error {
+	if !c.ok() {
+		return errInvalidConn
+	}
+	grp, src, err := groupSourceIP4(group, source)
+	if err != nil {
+		return err
+	}
+	return c.so.SysFD(func(fd int) error {
+		return blockUnblockSource(fd, ifi, grp, src, true)
+	})
+}
+
+// IncludeSourceSpecificGroup reverses a previous
+// ExcludeSourceSpecificGroup, re-admitting traffic from source.
+func (c *dgramOpt) IncludeSourceSpecificGroup(ifi *net.Interface, group, source net.Addr) error {
+	if !c.ok() {
+		return errInvalidConn
+	}
+	grp, src, err := groupSourceIP4(group, source)
+	if err != nil {
+		return err
+	}
+	return c.so.SysFD(func(fd int) error {
+		return blockUnblockSource(fd, ifi, grp, src, false)
+	})
+}
+
+// groupSourceIP4 resolves the group and source net.Addrs common to all
+// four SSM methods above down to their IPv4 net.IPs, failing with
+// errMissingAddress if either doesn't carry one.
+func groupSourceIP4(group, source net.Addr) (grp, src net.IP, err error) {
+	grp = netAddrToIP4(group)
+	if grp == nil {
+		return nil, nil, errMissingAddress
+	}
+	src = netAddrToIP4(source)
+	if src == nil {
+		return nil, nil, errMissingAddress
+	}
+	return grp, src, nil
+}
+
+func netAddrToIP4(a net.Addr) net.IP {
+	switch a := a.(type) {
+	case *net.UDPAddr:
+		return a.IP.To4()
+	case *net.IPAddr:
+		return a.IP.To4()
+	default:
+		return nil
+	}
+}
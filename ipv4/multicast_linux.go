@@ -0,0 +1,115 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipv4
+
+import (
+	"net"
+	"syscall"
+
+	"golang.org/x/net/internal/socket"
+)
+
+// Protocol-independent MCAST_* socket options, present on kernels new
+// enough to support SSM via the generic group_source_req path. On
+// older kernels, setsockopt fails with ENOPROTOOPT; joins and leaves
+// then fall back to the IPv4-only IP_*_SOURCE_MEMBERSHIP options
+// below, while blockUnblockSource has no fallback and returns that
+// error.
+const (
+	sysMCAST_JOIN_SOURCE_GROUP  = 46
+	sysMCAST_LEAVE_SOURCE_GROUP = 47
+	sysMCAST_BLOCK_SOURCE       = 43
+	sysMCAST_UNBLOCK_SOURCE     = 44
+)
+
+func joinLeaveSourceSpecificGroup(fd int, ifi *net.Interface, group, source net.IP, join bool) error {
+	opt := sysMCAST_JOIN_SOURCE_GROUP
+	if !join {
+		opt = sysMCAST_LEAVE_SOURCE_GROUP
+	}
+	err := setGroupSourceReq(fd, opt, ifi, group, source)
+	if err == syscall.ENOPROTOOPT || err == syscall.EOPNOTSUPP {
+		return setIPMreqSource(fd, ifi, group, source, join)
+	}
+	return err
+}
+
+func blockUnblockSource(fd int, ifi *net.Interface, group, source net.IP, block bool) error {
+	opt := sysMCAST_BLOCK_SOURCE
+	if !block {
+		opt = sysMCAST_UNBLOCK_SOURCE
+	}
+	return setGroupSourceReq(fd, opt, ifi, group, source)
+}
+
+func setGroupSourceReq(fd, opt int, ifi *net.Interface, group, source net.IP) error {
+	var ifIndex int
+	if ifi != nil {
+		ifIndex = ifi.Index
+	}
+	gsr := socket.GroupSourceReq{
+		Interface: ifIndex,
+		Group:     &net.IPAddr{IP: group},
+		Source:    &net.IPAddr{IP: source},
+	}
+	b, err := gsr.Marshal()
+	if err != nil {
+		return err
+	}
+	return setsockoptBytes(fd, syscall.IPPROTO_IP, opt, b)
+}
+
+// setIPMreqSource falls back to the IPv4-only ip_mreq_source options
+// for kernels without the protocol-independent MCAST_* calls.
+//
+//	struct ip_mreq_source {
+//		struct in_addr imr_multiaddr;
+//		struct in_addr imr_interface;
+//		struct in_addr imr_sourceaddr;
+//	};
+func setIPMreqSource(fd int, ifi *net.Interface, group, source net.IP, join bool) error {
+	opt := sysIP_ADD_SOURCE_MEMBERSHIP
+	if !join {
+		opt = sysIP_DROP_SOURCE_MEMBERSHIP
+	}
+	var mreq [12]byte // struct ip_mreq_source
+	copy(mreq[0:4], group.To4())
+	if ifi != nil {
+		if ip, err := interfaceIPv4Addr(ifi); err == nil {
+			copy(mreq[4:8], ip.To4())
+		}
+	}
+	copy(mreq[8:12], source.To4())
+	return setsockoptBytes(fd, syscall.IPPROTO_IP, opt, mreq[:])
+}
+
+func interfaceIPv4Addr(ifi *net.Interface) (net.IP, error) {
+	addrs, err := ifi.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range addrs {
+		var ip net.IP
+		switch a := a.(type) {
+		case *net.IPAddr:
+			ip = a.IP
+		case *net.IPNet:
+			ip = a.IP
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			return ip4, nil
+		}
+	}
+	return nil, errMissingAddress
+}
+
+const (
+	sysIP_ADD_SOURCE_MEMBERSHIP  = 39
+	sysIP_DROP_SOURCE_MEMBERSHIP = 40
+)
+
+func setsockoptBytes(fd, level, opt int, b []byte) error {
+	return syscall.SetsockoptString(fd, level, opt, string(b))
+}
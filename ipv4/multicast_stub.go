@@ -0,0 +1,17 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !linux
+
+package ipv4
+
+import "net"
+
+func joinLeaveSourceSpecificGroup(fd int, ifi *net.Interface, group, source net.IP, join bool) error {
+	return errNotSupported
+}
+
+func blockUnblockSource(fd int, ifi *net.Interface, group, source net.IP, block bool) error {
+	return errNotSupported
+}
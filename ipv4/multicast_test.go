@@ -0,0 +1,73 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipv4_test
+
+import (
+	"net"
+	"runtime"
+	"testing"
+
+	"golang.org/x/net/ipv4"
+)
+
+// TestPacketConnJoinLeaveSourceSpecificGroup exercises the SSM
+// join/leave path against a well-known source-specific multicast
+// address in 232.0.0.0/8.
+func TestPacketConnJoinLeaveSourceSpecificGroup(t *testing.T) {
+	switch runtime.GOOS {
+	case "linux":
+	default:
+		t.Skipf("not supported on %s", runtime.GOOS)
+	}
+
+	c, err := net.ListenPacket("udp4", "0.0.0.0:0")
+	if err != nil {
+		t.Fatalf("ListenPacket failed: %v", err)
+	}
+	defer c.Close()
+
+	p := ipv4.NewPacketConn(c)
+	group := &net.UDPAddr{IP: net.IPv4(232, 0, 0, 1)}
+	source := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}
+
+	if err := p.JoinSourceSpecificGroup(nil, group, source); err != nil {
+		t.Skipf("JoinSourceSpecificGroup failed, probably no multicast-capable interface: %v", err)
+	}
+	if err := p.LeaveSourceSpecificGroup(nil, group, source); err != nil {
+		t.Fatalf("LeaveSourceSpecificGroup failed: %v", err)
+	}
+}
+
+// TestPacketConnExcludeIncludeSourceSpecificGroup exercises the
+// source filter toggling path on an already-joined any-source group.
+func TestPacketConnExcludeIncludeSourceSpecificGroup(t *testing.T) {
+	switch runtime.GOOS {
+	case "linux":
+	default:
+		t.Skipf("not supported on %s", runtime.GOOS)
+	}
+
+	c, err := net.ListenPacket("udp4", "0.0.0.0:0")
+	if err != nil {
+		t.Fatalf("ListenPacket failed: %v", err)
+	}
+	defer c.Close()
+
+	p := ipv4.NewPacketConn(c)
+	group := &net.UDPAddr{IP: net.IPv4(232, 0, 0, 2)}
+	source := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}
+
+	if err := p.JoinGroup(nil, group); err != nil {
+		t.Skipf("JoinGroup failed, probably no multicast-capable interface: %v", err)
+	}
+	defer p.LeaveGroup(nil, group)
+
+	if err := p.ExcludeSourceSpecificGroup(nil, group, source); err != nil {
+		t.Fatalf("ExcludeSourceSpecificGroup failed: %v", err)
+	}
+	if err := p.IncludeSourceSpecificGroup(nil, group, source); err != nil {
+		t.Fatalf("IncludeSourceSpecificGroup failed: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipv4
+
+import (
+	"net"
+	"syscall"
+	"unsafe"
+)
+
+// sysIP_RECVERR enables delivery of the IP_RECVERR control message;
+// there's no syscall.IP_RECVERR constant in every architecture's
+// generated syscall package, so it's spelled out here.
+const sysIP_RECVERR = 11
+
+// Origins for RecvErr.Origin, mirroring SO_EE_ORIGIN_* in
+// linux/errqueue.h.
+const (
+	RecvErrOriginNone = iota
+	RecvErrOriginLocal
+	RecvErrOriginICMP
+	RecvErrOriginICMP6
+)
+
+// A RecvErr is the decoded form of an IP_RECVERR control message
+// delivered alongside a datagram read from a socket that has
+// SetRecvErr(true) set, mirroring the kernel's struct
+// sock_extended_err.
+//
+// When Origin is RecvErrOriginLocal and Err is syscall.EMSGSIZE, Info
+// carries the kernel's updated path MTU estimate for the destination
+// that rejected the oversized, DF-marked datagram -- the same value
+// PathMTU would return, but delivered with the failed write instead
+// of requiring a separate query.
+type RecvErr struct {
+	Err      error
+	Origin   int
+	Type     int
+	Code     int
+	Info     uint32
+	Offender net.Addr
+}
+
+// sockExtendedErr mirrors the kernel's struct sock_extended_err.
+type sockExtendedErr struct {
+	Errno  uint32
+	Origin uint8
+	Type   uint8
+	Code   uint8
+	Pad    uint8
+	Info   uint32
+	Data   uint32
+}
+
+const sizeofSockExtendedErr = 16
+
+// SetRecvErr enables or disables delivery of the IP_RECVERR control
+// message. Once enabled, a caller can pass the OOB bytes returned by
+// ReadFrom to ParseRecvErr to learn of ICMP "fragmentation needed"
+// and other asynchronous errors alongside the packet, instead of
+// discovering them only on the next write.
+func (c *dgramOpt) SetRecvErr(on bool) error {
+	if !c.ok() {
+		return errInvalidConn
+	}
+	return c.so.SysFD(func(fd int) error {
+		v := 0
+		if on {
+			v = 1
+		}
+		return syscall.SetsockoptInt(fd, syscall.IPPROTO_IP, sysIP_RECVERR, v)
+	})
+}
+
+// ParseRecvErr scans oob, the out-of-band bytes returned alongside a
+// ReadFrom, for an IP_RECVERR control message and decodes it. It
+// returns nil, nil if oob carries no such message.
+func ParseRecvErr(oob []byte) (*RecvErr, error) {
+	scms, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil {
+		return nil, err
+	}
+	for _, scm := range scms {
+		if scm.Header.Level != syscall.IPPROTO_IP || int(scm.Header.Type) != sysIP_RECVERR {
+			continue
+		}
+		if len(scm.Data) < sizeofSockExtendedErr {
+			continue
+		}
+		ee := (*sockExtendedErr)(unsafe.Pointer(&scm.Data[0]))
+		re := &RecvErr{
+			Err:    syscall.Errno(ee.Errno),
+			Origin: int(ee.Origin),
+			Type:   int(ee.Type),
+			Code:   int(ee.Code),
+			Info:   ee.Info,
+		}
+		if off := scm.Data[sizeofSockExtendedErr:]; len(off) >= syscall.SizeofSockaddrInet4 {
+			sa4 := (*syscall.RawSockaddrInet4)(unsafe.Pointer(&off[0]))
+			re.Offender = netAddrFromSockaddrInet4(sa4)
+		}
+		return re, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,33 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !linux
+
+package ipv4
+
+import "net"
+
+// A RecvErr is the decoded form of an IP_RECVERR control message.
+// IP_RECVERR is Linux-specific; the RecvErr type is defined on every
+// platform, but the RecvErrOrigin constants are defined on Linux only.
+type RecvErr struct {
+	Err      error
+	Origin   int
+	Type     int
+	Code     int
+	Info     uint32
+	Offender net.Addr
+}
+
+func (c *dgramOpt) SetRecvErr(on bool) error {
+	if !c.ok() {
+		return errInvalidConn
+	}
+	return errNotSupported
+}
+
+// ParseRecvErr always fails on platforms other than Linux.
+func ParseRecvErr(oob []byte) (*RecvErr, error) {
+	return nil, errNotSupported
+}